@@ -9,6 +9,7 @@ import (
 
 var (
 	ErrTooSmallStep = errors.Errorf("too small step")
+	ErrTooManySteps = errors.Errorf("too many steps")
 )
 
 // DydxFunc returns the derivative dy/dx given x and y.
@@ -16,38 +17,120 @@ type DydxFunc func(dydx []float64, x float64, y []float64)
 
 // DormandPrince performs the [Dormand-Prince] method.
 //
+// sol is non-nil and interpolates a continuous solution over xspan. events
+// holds the located crossings of Options.Events, in the order they occur.
+//
 // [Dormand-Prince]: https://en.wikipedia.org/wiki/Dormand%E2%80%93Prince_method
-func DormandPrince(dydxFunc DydxFunc, xspan [2]float64, y0 []float64) (xs []float64, ys [][]float64, err error) {
+func DormandPrince(dydxFunc DydxFunc, xspan [2]float64, y0 []float64, opts ...Option) (xs []float64, ys [][]float64, sol *Solution, events []EventCrossing, err error) {
 	rk := &dormandPrince{}
-	return rungeKuttaIntegrate(rk, dydxFunc, xspan, y0)
+	return rungeKuttaIntegrate(rk, dydxFunc, xspan, y0, newOptions(opts...))
 }
 
 type rungeKutta interface {
 	errorOrder() int
-	yPlusH(yPlusH, te []float64, dydxFunc DydxFunc, x float64, y []float64, h float64)
+	stages() int
+	yPlusH(yPlusH, te []float64, k [][]float64, dydxFunc DydxFunc, x float64, y []float64, h float64)
+	// piDefaults returns the method-specific (alpha, beta) exponents of the
+	// Gustafsson PI step-size controller.
+	piDefaults() (alpha, beta float64)
 }
 
-func rungeKuttaIntegrate(rk rungeKutta, dydxFunc DydxFunc, xspan [2]float64, y0 []float64) (xs []float64, ys [][]float64, err error) {
+func rungeKuttaIntegrate(rk rungeKutta, dydxFunc DydxFunc, xspan [2]float64, y0 []float64, opts Options) (xs []float64, ys [][]float64, sol *Solution, events []EventCrossing, err error) {
+	if err := opts.validate(len(y0)); err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "")
+	}
+
 	xs = append(xs, xspan[0])
 	ys = append(ys, y0)
 
-	tol := tolerance{abs: 1e-6, rel: 1e-3}
-	h := getFirstStep(rk, dydxFunc, xspan, y0, tol)
+	interp, dense := rk.(denseOutputter)
+	if dense {
+		sol = &Solution{interp: interp}
+	}
+	if len(opts.Events) > 0 && !dense {
+		return nil, nil, nil, nil, errors.Wrap(ErrEventsNeedDenseOutput, "")
+	}
+
+	tol := opts.tolerance()
+	h := opts.InitialStep
+	if h == 0 {
+		h = getFirstStep(rk, dydxFunc, xspan, y0, tol)
+	}
+	if opts.MaxStep > 0 {
+		h = min(h, opts.MaxStep)
+	}
+
+	maxSteps := opts.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = 100000
+	}
+
+	k := make([][]float64, rk.stages())
+	for i := range k {
+		k[i] = make([]float64, len(y0))
+	}
+
+	maxRejections := opts.MaxRejections
+	if maxRejections == 0 {
+		maxRejections = 12
+	}
+	ctrl := newStepController(rk, opts)
 
 	var x float64 = xspan[0]
 	y := make([]float64, len(y0))
 	copy(y, y0)
 	te := make([]float64, len(y0))
-	for x < xspan[1] {
+	for n := 0; x < xspan[1]; n++ {
+		if n >= maxSteps {
+			return nil, nil, nil, nil, errors.Wrap(ErrTooManySteps, "")
+		}
+
 		yPlusH := make([]float64, len(y0))
-		xPlusH, newH, err := rungeKuttaStep(yPlusH, te, rk, dydxFunc, x, y, h, xspan[1], tol)
+		xPlusH, newH, err := rungeKuttaStep(yPlusH, te, k, rk, dydxFunc, x, y, h, xspan[1], tol, opts.MinStep, opts.MaxStep, ctrl, maxRejections)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "")
+			return nil, nil, nil, nil, errors.Wrap(err, "")
+		}
+		if dense {
+			sol.appendStep(x, xPlusH-x, y, yPlusH, k)
 		}
 
-		// Add to result.
-		xs = append(xs, xPlusH)
-		ys = append(ys, yPlusH)
+		// Locate any events within (x, xPlusH] and truncate at the first
+		// terminal one.
+		observeX, observeY, observeH := xPlusH, yPlusH, xPlusH-x
+		terminated := false
+		if len(opts.Events) > 0 {
+			points, crossings, err := locateStepEvents(interp, opts.Events, x, xPlusH-x, y, yPlusH, k)
+			if err != nil {
+				return nil, nil, nil, nil, errors.Wrap(err, "locateStepEvents")
+			}
+			events = append(events, crossings...)
+			for _, p := range points {
+				xs = append(xs, p.x)
+				ys = append(ys, p.y)
+				if p.terminal {
+					observeX, observeY, observeH = p.x, p.y, p.x-x
+					terminated = true
+					break
+				}
+			}
+		}
+		if !terminated {
+			xs = append(xs, xPlusH)
+			ys = append(ys, yPlusH)
+		}
+
+		// Report the point the trajectory actually stopped at this step,
+		// not the raw accepted step's endpoint, so the observer never sees
+		// x past a terminal event's truncation.
+		if opts.StepObserver != nil {
+			if err := opts.StepObserver(observeX, observeY, observeH); err != nil {
+				return nil, nil, nil, nil, errors.Wrap(err, "StepObserver")
+			}
+		}
+
+		if terminated {
+			break
+		}
 
 		// Update iteration state.
 		x = xPlusH
@@ -58,13 +141,16 @@ func rungeKuttaIntegrate(rk rungeKutta, dydxFunc DydxFunc, xspan [2]float64, y0
 	return
 }
 
-func rungeKuttaStep(yPlusH, te []float64, rungeKutta rungeKutta, dydxFunc DydxFunc, x float64, y []float64, h, xMax float64, tol tolerance) (xPlusH float64, newH float64, err error) {
-	minStep := 10 * math.Abs(math.Nextafter(x, math.Inf(1))-x)
-	rejected := false
+func rungeKuttaStep(yPlusH, te []float64, k [][]float64, rungeKutta rungeKutta, dydxFunc DydxFunc, x float64, y []float64, h, xMax float64, tol tolerance, minStepOpt, maxStepOpt float64, ctrl *stepController, maxRejections int) (xPlusH float64, newH float64, err error) {
+	minStep := max(10*math.Abs(math.Nextafter(x, math.Inf(1))-x), minStepOpt)
+	rejections := 0
 	for {
 		if h < minStep {
 			return math.NaN(), math.NaN(), ErrTooSmallStep
 		}
+		if maxStepOpt > 0 {
+			h = min(h, maxStepOpt)
+		}
 
 		xPlusH = x + h
 		if xPlusH > xMax {
@@ -73,12 +159,13 @@ func rungeKuttaStep(yPlusH, te []float64, rungeKutta rungeKutta, dydxFunc DydxFu
 		}
 
 		// Compute yPlusH and te.
-		rungeKutta.yPlusH(yPlusH, te, dydxFunc, x, y, h)
+		rungeKutta.yPlusH(yPlusH, te, k, dydxFunc, x, y, h)
 
 		// Compute errorNorm.
 		scale := make([]float64, len(y))
 		for i := range scale {
-			scale[i] = tol.abs + tol.rel*max(math.Abs(y[i]), math.Abs(yPlusH[i]))
+			abs, rel := tol.at(i)
+			scale[i] = abs + rel*max(math.Abs(y[i]), math.Abs(yPlusH[i]))
 		}
 		var errorNorm float64
 		for i := range te {
@@ -86,28 +173,22 @@ func rungeKuttaStep(yPlusH, te []float64, rungeKutta rungeKutta, dydxFunc DydxFu
 		}
 		errorNorm = math.Sqrt(errorNorm / float64(len(te)))
 
-		// Compute new h.
-		const maxFactor = 10
-		const minFactor = 0.2
-		const safety = 0.9
-		exponent := -1 / (float64(rungeKutta.errorOrder()) + 1)
-		var factor float64
+		// Compute new h with the PI controller.
 		if errorNorm < 1 {
-			if errorNorm == 0 {
-				factor = maxFactor
-			} else {
-				factor = min(safety*math.Pow(errorNorm, exponent), maxFactor)
-			}
-			if rejected {
+			factor := ctrl.accept(errorNorm, rungeKutta.errorOrder())
+			if rejections > 0 {
 				factor = min(1, factor)
 			}
 
 			newH = h * factor
 			return
-		} else {
-			h *= max(safety*math.Pow(errorNorm, exponent), minFactor)
-			rejected = true
 		}
+
+		rejections++
+		if rejections > maxRejections {
+			return math.NaN(), math.NaN(), ErrTooManyRejections
+		}
+		h *= ctrl.reject(errorNorm, rungeKutta.errorOrder())
 	}
 }
 
@@ -119,7 +200,8 @@ func getFirstStep(rungeKutta rungeKutta, dydxFunc func(dydx []float64, x float64
 	// Compute d0, d1.
 	scale := make([]float64, len(y0))
 	for i, y := range y0 {
-		scale[i] = tol.abs + math.Abs(y)*tol.rel
+		abs, rel := tol.at(i)
+		scale[i] = abs + math.Abs(y)*rel
 	}
 	var d0, d1 float64
 	for i := range scale {
@@ -163,9 +245,27 @@ func getFirstStep(rungeKutta rungeKutta, dydxFunc func(dydx []float64, x float64
 	return min(100*h0, h1, xspan[1]-xspan[0])
 }
 
+// tolerance holds the absolute and relative tolerances used in the error
+// norm. absVec and relVec, if non-nil, override abs and rel on a
+// per-component basis.
 type tolerance struct {
 	abs float64
 	rel float64
+
+	absVec []float64
+	relVec []float64
+}
+
+// at returns the absolute and relative tolerance for component i.
+func (t tolerance) at(i int) (abs, rel float64) {
+	abs, rel = t.abs, t.rel
+	if t.absVec != nil {
+		abs = t.absVec[i]
+	}
+	if t.relVec != nil {
+		rel = t.relVec[i]
+	}
+	return abs, rel
 }
 
 type dormandPrince struct{}
@@ -174,7 +274,17 @@ func (dp *dormandPrince) errorOrder() int {
 	return 4
 }
 
-func (dp *dormandPrince) yPlusH(yPlusH, te []float64, dydxFunc DydxFunc, x float64, y []float64, h float64) {
+func (dp *dormandPrince) stages() int {
+	return 7
+}
+
+// piDefaults returns the (alpha, beta) exponents recommended by Gustafsson
+// for order-5 explicit methods.
+func (dp *dormandPrince) piDefaults() (alpha, beta float64) {
+	return 0.7, 0.4
+}
+
+func (dp *dormandPrince) yPlusH(yPlusH, te []float64, k [][]float64, dydxFunc DydxFunc, x float64, y []float64, h float64) {
 	c := []float64{0, 1. / 5, 3. / 10, 4. / 5, 8. / 9, 1, 1}
 	a := [][]float64{
 		{0, 0, 0, 0, 0, 0},
@@ -188,11 +298,6 @@ func (dp *dormandPrince) yPlusH(yPlusH, te []float64, dydxFunc DydxFunc, x float
 	b := []float64{35. / 384, 0, 500. / 1113, 125. / 192, -2187. / 6784, 11. / 84, 0}
 	e := []float64{-71. / 57600, 0, 71. / 16695, -71. / 1920, 17253. / 339200, -22. / 525, 1. / 40}
 
-	k := make([][]float64, len(c))
-	for i := range k {
-		k[i] = make([]float64, len(y))
-	}
-
 	yItp := make([]float64, len(y))
 	for i := range k {
 		xItp := x + c[i]*h
@@ -219,3 +324,29 @@ func (dp *dormandPrince) yPlusH(yPlusH, te []float64, dydxFunc DydxFunc, x float
 		}
 	}
 }
+
+// interpolate evaluates the natural quartic interpolant of Dormand-Prince
+// 5(4) at x+theta*h, theta in [0,1].
+//
+// E. Hairer, S. P. Norsett, G. Wanner, "Solving Ordinary Differential
+// Equations I: Nonstiff Problems", Sec. II.6.
+func (dp *dormandPrince) interpolate(out, y0, y1 []float64, k [][]float64, h, theta float64) {
+	const (
+		d1 = -12715105075. / 11282082432.
+		d3 = 87487479700. / 32700410799.
+		d4 = -10690763975. / 1880347072.
+		d5 = 701980252875. / 199316789632.
+		d6 = -1453857185. / 822651844.
+		d7 = 69997945. / 29380423.
+	)
+
+	for i := range out {
+		cont1 := y0[i]
+		cont2 := y1[i] - y0[i]
+		cont3 := h*k[0][i] - cont2
+		cont4 := cont2 - h*k[6][i] - cont3
+		cont5 := h * (d1*k[0][i] + d3*k[2][i] + d4*k[3][i] + d5*k[4][i] + d6*k[5][i] + d7*k[6][i])
+
+		out[i] = cont1 + theta*(cont2+(1-theta)*(cont3+theta*(cont4+(1-theta)*cont5)))
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"math"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 func TestDormandPrince(t *testing.T) {
@@ -51,7 +53,7 @@ func TestDormandPrince(t *testing.T) {
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
 			t.Parallel()
-			xs, ys, err := DormandPrince(test.dydx, test.xspan, test.y0)
+			xs, ys, _, _, err := DormandPrince(test.dydx, test.xspan, test.y0)
 			if err != nil {
 				t.Fatalf("%+v", err)
 			}
@@ -71,6 +73,171 @@ func TestDormandPrince(t *testing.T) {
 	}
 }
 
+func TestToleranceVecLengthMismatch(t *testing.T) {
+	dydx := func(dydx []float64, x float64, y []float64) {
+		dydx[0] = -y[1]
+		dydx[1] = y[0]
+	}
+	xspan := [2]float64{0, 1}
+	y0 := []float64{1, 1}
+
+	_, _, _, _, err := DormandPrince(dydx, xspan, y0, WithAbsTolVec([]float64{1e-6}))
+	if errors.Cause(err) != ErrToleranceVecLength {
+		t.Fatalf("err %+v, want ErrToleranceVecLength", err)
+	}
+}
+
+// fakeDenseOutputter interpolates linearly between y0 and y1, letting tests
+// exercise Solution.Evaluate's segment lookup without running a solver.
+type fakeDenseOutputter struct{}
+
+func (fakeDenseOutputter) errorOrder() int                   { return 1 }
+func (fakeDenseOutputter) stages() int                       { return 1 }
+func (fakeDenseOutputter) piDefaults() (alpha, beta float64) { return 0, 0 }
+func (fakeDenseOutputter) yPlusH(yPlusH, te []float64, k [][]float64, dydxFunc DydxFunc, x float64, y []float64, h float64) {
+}
+func (fakeDenseOutputter) interpolate(out, y0, y1 []float64, k [][]float64, h, theta float64) {
+	for i := range out {
+		out[i] = y0[i] + theta*(y1[i]-y0[i])
+	}
+}
+
+func TestSolutionEvaluateLastSegment(t *testing.T) {
+	sol := &Solution{interp: fakeDenseOutputter{}}
+	sol.appendStep(0, 1, []float64{0}, []float64{10}, nil)
+	sol.appendStep(1, 1, []float64{10}, []float64{11}, nil)
+	sol.appendStep(2, 1, []float64{11}, []float64{111}, nil)
+
+	out := make([]float64, 1)
+	sol.Evaluate(2.5, out)
+	if diff := math.Abs(out[0] - 61); diff > 1e-9 {
+		t.Errorf("out[0] %f, want 61 (diff %f)", out[0], diff)
+	}
+}
+
+func TestSolutionEvaluateSingleStep(t *testing.T) {
+	sol := &Solution{interp: fakeDenseOutputter{}}
+	sol.appendStep(0, 2, []float64{0}, []float64{10}, nil)
+
+	out := make([]float64, 1)
+	sol.Evaluate(1, out)
+	if diff := math.Abs(out[0] - 5); diff > 1e-9 {
+		t.Errorf("out[0] %f, want 5 (diff %f)", out[0], diff)
+	}
+}
+
+func TestSolution(t *testing.T) {
+	dydx := func(dydx []float64, x float64, y []float64) {
+		dydx[0] = -y[1]
+		dydx[1] = y[0]
+	}
+	xspan := [2]float64{0, 4}
+	y0 := []float64{1, 1}
+
+	_, _, sol, _, err := DormandPrince(dydx, xspan, y0, WithAbsTol(1e-9), WithRelTol(1e-9))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	out := make([]float64, 2)
+	for x := xspan[0]; x < xspan[1]; x += 0.1 {
+		sol.Evaluate(x, out)
+
+		yTrue := []float64{math.Cos(x) - math.Sin(x), math.Cos(x) + math.Sin(x)}
+		for j := range out {
+			if diff := math.Abs(out[j] - yTrue[j]); diff > 1e-6 {
+				t.Errorf("x %f out[%d] %f %f diff %f", x, j, out[j], yTrue[j], diff)
+			}
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	// dy/dx = -y, y(0) = 1, so y crosses 0.5 at x = ln(2).
+	dydx := func(dydx []float64, x float64, y []float64) {
+		dydx[0] = -y[0]
+	}
+	xspan := [2]float64{0, 4}
+	y0 := []float64{1}
+
+	events := []Event{
+		{Func: func(x float64, y []float64) float64 { return y[0] - 0.5 }, Direction: -1, Terminal: true},
+	}
+	xs, ys, _, crossings, err := DormandPrince(dydx, xspan, y0, WithEvents(events), WithAbsTol(1e-9), WithRelTol(1e-9))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(crossings) != 1 {
+		t.Fatalf("crossings %d", len(crossings))
+	}
+	xTrue := math.Log(2)
+	if diff := math.Abs(crossings[0].X - xTrue); diff > 1e-6 {
+		t.Errorf("x %f xTrue %f diff %f", crossings[0].X, xTrue, diff)
+	}
+
+	lastX := xs[len(xs)-1]
+	if diff := math.Abs(lastX - xTrue); diff > 1e-6 {
+		t.Errorf("integration did not stop at the event: lastX %f xTrue %f", lastX, xTrue)
+	}
+	if diff := math.Abs(ys[len(ys)-1][0] - 0.5); diff > 1e-6 {
+		t.Errorf("y at event %f", ys[len(ys)-1][0])
+	}
+}
+
+func TestRadau5(t *testing.T) {
+	// dy/dx = -50*(y - cos(x)) - sin(x), y(0) = 1, stiff towards y = cos(x).
+	dydx := func(dydx []float64, x float64, y []float64) {
+		dydx[0] = -50*(y[0]-math.Cos(x)) - math.Sin(x)
+	}
+	jac := func(dfdy [][]float64, x float64, y []float64) {
+		dfdy[0][0] = -50
+	}
+	xspan := [2]float64{0, 2}
+	y0 := []float64{1}
+
+	xs, ys, _, _, err := Radau5(dydx, xspan, y0, WithJacobian(jac))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for i := range xs {
+		x := xs[i]
+		yTrue := math.Cos(x)
+		if diff := math.Abs(ys[i][0] - yTrue); diff > 1e-2 {
+			t.Errorf("x %f y %f yTrue %f diff %f", x, ys[i][0], yTrue, diff)
+		}
+	}
+}
+
+func TestRadau5FewerStepsThanDormandPrince(t *testing.T) {
+	// Same stiff problem as TestRadau5: DormandPrince should collapse to
+	// tiny steps while Radau5, the implicit stiff solver, should not.
+	dydx := func(dydx []float64, x float64, y []float64) {
+		dydx[0] = -50*(y[0]-math.Cos(x)) - math.Sin(x)
+	}
+	jac := func(dfdy [][]float64, x float64, y []float64) {
+		dfdy[0][0] = -50
+	}
+	xspan := [2]float64{0, 2}
+	y0 := []float64{1}
+
+	xsDP, _, _, _, err := DormandPrince(dydx, xspan, y0)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	xsRadau, _, _, _, err := Radau5(dydx, xspan, y0, WithJacobian(jac))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	stepsDP := len(xsDP) - 1
+	stepsRadau := len(xsRadau) - 1
+	if stepsRadau >= stepsDP {
+		t.Errorf("Radau5 took %d steps, DormandPrince took %d; expected Radau5 to need fewer steps on a stiff problem", stepsRadau, stepsDP)
+	}
+}
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	log.SetFlags(log.Lmicroseconds | log.Llongfile | log.LstdFlags)
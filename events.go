@@ -0,0 +1,134 @@
+package diffeq
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var ErrEventsNeedDenseOutput = errors.Errorf("events require a dense-output-capable method")
+
+// Event describes a zero-crossing of Func to locate during integration.
+type Event struct {
+	// Func is evaluated at x, y during integration; a sign change of its
+	// return value between two accepted steps is located by bisection on
+	// the dense-output interpolant.
+	Func func(x float64, y []float64) float64
+	// Direction restricts which crossings are located: -1 only falling
+	// (Func going from positive to negative), +1 only rising, 0 any.
+	Direction int
+	// Terminal stops the integration at the located crossing.
+	Terminal bool
+}
+
+// EventCrossing is a zero crossing of an Event located during integration.
+type EventCrossing struct {
+	// Index is the position of the triggering Event in Options.Events.
+	Index int
+	X     float64
+	Y     []float64
+}
+
+// WithEvents registers events to locate during integration. Events require
+// a dense-output-capable method such as DormandPrince.
+func WithEvents(events []Event) Option {
+	return func(o *Options) { o.Events = events }
+}
+
+// crossed reports whether g changed sign from g0 to g1 in the direction
+// required by d.
+func crossed(d int, g0, g1 float64) bool {
+	switch {
+	case d > 0:
+		return g0 < 0 && g1 >= 0
+	case d < 0:
+		return g0 > 0 && g1 <= 0
+	default:
+		return (g0 < 0) != (g1 < 0)
+	}
+}
+
+type eventPoint struct {
+	x        float64
+	y        []float64
+	terminal bool
+}
+
+// locateStepEvents evaluates every event at the endpoints of the accepted
+// step (x, x+h) and, for each one that crosses zero, bisects on the
+// dense-output interpolant to locate x*. The returned points and crossings
+// are both sorted by x* and truncated after the first terminal crossing, if
+// any, so neither ever reports an event past the point the integration
+// actually stopped at.
+func locateStepEvents(interp denseOutputter, events []Event, x, h float64, y0, y1 []float64, k [][]float64) (points []eventPoint, crossings []EventCrossing, err error) {
+	if interp == nil {
+		return nil, nil, errors.Wrap(ErrEventsNeedDenseOutput, "")
+	}
+
+	var found []EventCrossing
+	var terminal []bool
+	for idx, ev := range events {
+		g0 := ev.Func(x, y0)
+		g1 := ev.Func(x+h, y1)
+		if !crossed(ev.Direction, g0, g1) {
+			continue
+		}
+
+		xStar, yStar := bisectEvent(interp, ev.Func, x, h, y0, y1, k)
+		found = append(found, EventCrossing{Index: idx, X: xStar, Y: yStar})
+		terminal = append(terminal, ev.Terminal)
+	}
+
+	order := make([]int, len(found))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return found[order[i]].X < found[order[j]].X })
+
+	for _, i := range order {
+		crossings = append(crossings, found[i])
+		points = append(points, eventPoint{x: found[i].X, y: found[i].Y, terminal: terminal[i]})
+		if terminal[i] {
+			break
+		}
+	}
+
+	return points, crossings, nil
+}
+
+const (
+	eventTol      = 1e-10
+	maxEventIters = 100
+)
+
+// bisectEvent locates the zero crossing of fn within the step (x, x+h) by
+// bisection on the dense-output interpolant.
+func bisectEvent(interp denseOutputter, fn func(x float64, y []float64) float64, x, h float64, y0, y1 []float64, k [][]float64) (float64, []float64) {
+	out := make([]float64, len(y0))
+	at := func(theta float64) float64 {
+		interp.interpolate(out, y0, y1, k, h, theta)
+		return fn(x+theta*h, out)
+	}
+
+	lo, hi := 0., 1.
+	gLo := at(lo)
+	for i := 0; i < maxEventIters && hi-lo > eventTol; i++ {
+		mid := (lo + hi) / 2
+		gMid := at(mid)
+		if math.Abs(gMid) < eventTol {
+			lo, hi = mid, mid
+			break
+		}
+		if (gMid < 0) == (gLo < 0) {
+			lo, gLo = mid, gMid
+		} else {
+			hi = mid
+		}
+	}
+
+	theta := (lo + hi) / 2
+	yStar := make([]float64, len(y0))
+	interp.interpolate(yStar, y0, y1, k, h, theta)
+	return x + theta*h, yStar
+}
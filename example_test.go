@@ -23,7 +23,7 @@ func Example() {
 	y0 := []float64{1, -1}
 
 	// Solve equation with the Dormand-Prince method.
-	xs, ys, err := diffeq.DormandPrince(dydx, xspan, y0)
+	xs, ys, _, _, err := diffeq.DormandPrince(dydx, xspan, y0)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
@@ -40,9 +40,13 @@ func Example() {
 	// x, y(x), z(x)
 	// 0.000, 1.000, -1.000
 	// 0.091, 0.917, -0.913
-	// 0.943, 0.868, -0.243
-	// 1.752, 2.144, 0.872
-	// 2.633, 6.065, 4.216
-	// 3.742, 20.124, 17.311
-	// 4.000, 26.328, 23.273
+	// 0.514, 0.733, -0.575
+	// 0.772, 0.775, -0.383
+	// 1.177, 1.085, -0.017
+	// 1.543, 1.660, 0.476
+	// 1.987, 2.853, 1.455
+	// 2.495, 5.182, 3.440
+	// 3.062, 9.754, 7.552
+	// 3.682, 18.900, 16.142
+	// 4.000, 26.327, 23.272
 }
@@ -0,0 +1,100 @@
+package diffeq
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSingularMatrix = errors.Errorf("singular matrix")
+
+// luFactorization is an in-place LU decomposition of a square matrix with
+// partial pivoting, used to solve the same system against multiple
+// right-hand sides (the Newton iterations of one step) without refactoring.
+// Each step attempt, a retry after a rejected step included, factors its
+// own matrix.
+type luFactorization struct {
+	lu  [][]float64
+	piv []int
+	n   int
+}
+
+// newLU factors m, which is consumed and overwritten.
+func newLU(m [][]float64) (*luFactorization, error) {
+	n := len(m)
+	piv := make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+
+	for col := 0; col < n; col++ {
+		// Partial pivoting: find the largest entry in this column.
+		p := col
+		best := math.Abs(m[col][col])
+		for row := col + 1; row < n; row++ {
+			if v := math.Abs(m[row][col]); v > best {
+				p, best = row, v
+			}
+		}
+		if best == 0 {
+			return nil, errors.Wrap(ErrSingularMatrix, "")
+		}
+		if p != col {
+			m[col], m[p] = m[p], m[col]
+			piv[col], piv[p] = piv[p], piv[col]
+		}
+
+		for row := col + 1; row < n; row++ {
+			factor := m[row][col] / m[col][col]
+			m[row][col] = factor
+			for k := col + 1; k < n; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+
+	return &luFactorization{lu: m, piv: piv, n: n}, nil
+}
+
+// solve writes the solution of L*U*x = b into x.
+func (f *luFactorization) solve(x, b []float64) {
+	n := f.n
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		y[i] = b[f.piv[i]]
+		for j := 0; j < i; j++ {
+			y[i] -= f.lu[i][j] * y[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		v := y[i]
+		for j := i + 1; j < n; j++ {
+			v -= f.lu[i][j] * x[j]
+		}
+		x[i] = v / f.lu[i][i]
+	}
+}
+
+// finiteDifferenceJacobian approximates df/dy at (x, y) by forward
+// differences, for use when no analytic Jacobian is supplied.
+func finiteDifferenceJacobian(dfdy [][]float64, dydxFunc DydxFunc, x float64, y []float64) {
+	n := len(y)
+	f0 := make([]float64, n)
+	dydxFunc(f0, x, y)
+
+	yPerturbed := make([]float64, n)
+	copy(yPerturbed, y)
+	f1 := make([]float64, n)
+	for j := 0; j < n; j++ {
+		eps := math.Sqrt(epsilon) * math.Max(1, math.Abs(y[j]))
+		yPerturbed[j] = y[j] + eps
+		dydxFunc(f1, x, yPerturbed)
+		yPerturbed[j] = y[j]
+
+		for i := 0; i < n; i++ {
+			dfdy[i][j] = (f1[i] - f0[i]) / eps
+		}
+	}
+}
+
+const epsilon = 2.220446049250313e-16
@@ -0,0 +1,162 @@
+package diffeq
+
+import "github.com/pkg/errors"
+
+// ErrToleranceVecLength is returned when AbsTolVec or RelTolVec does not
+// have the same length as y0.
+var ErrToleranceVecLength = errors.Errorf("tolerance vector length does not match y0")
+
+// Options configures the behavior of an ODE solver such as DormandPrince.
+//
+// Construct one via the With* functions passed as variadic arguments; the
+// zero value is never used directly since solvers apply their own defaults
+// first.
+type Options struct {
+	// AbsTol and RelTol are the scalar absolute and relative tolerances used
+	// in the error norm. They are ignored for components overridden by
+	// AbsTolVec or RelTolVec.
+	AbsTol float64
+	RelTol float64
+	// AbsTolVec and RelTolVec, if non-nil, override AbsTol and RelTol on a
+	// per-component basis. They must have the same length as y0.
+	AbsTolVec []float64
+	RelTolVec []float64
+
+	// InitialStep overrides the automatically estimated initial step size.
+	// If zero, the solver estimates it following Hairer & Wanner.
+	InitialStep float64
+	// MinStep is the smallest step size the solver may take before giving
+	// up with ErrTooSmallStep. If zero, only the step size imposed by the
+	// floating point precision of x is enforced.
+	MinStep float64
+	// MaxStep caps the step size the controller may grow to. If zero, no
+	// cap beyond the span of xspan is applied.
+	MaxStep float64
+	// MaxSteps caps the number of accepted steps before the solver gives up
+	// with ErrTooManySteps. If zero, a default of 100000 is used.
+	MaxSteps int
+
+	// StepObserver, if set, is invoked after every accepted step with the
+	// new x, y and the step size h that produced it. Returning a non-nil
+	// error aborts the integration; the error is returned from the solver
+	// wrapped with context.
+	StepObserver func(x float64, y []float64, h float64) error
+
+	// Events, if set, are located during integration. See WithEvents.
+	Events []Event
+
+	// Jac, if set, computes the Jacobian df/dy at (x, y) into dfdy for
+	// implicit solvers such as Radau5. If nil, a finite-difference
+	// approximation is used instead.
+	Jac func(dfdy [][]float64, x float64, y []float64)
+
+	// PIAlpha and PIBeta override the method's default exponents in the
+	// Gustafsson PI step-size controller. Zero means "use the method's
+	// default".
+	PIAlpha float64
+	PIBeta  float64
+	// MaxRejections caps the number of consecutive rejected steps before
+	// the solver gives up with ErrTooManyRejections. If zero, a default of
+	// 12 is used.
+	MaxRejections int
+}
+
+// Option configures an Options value.
+type Option func(*Options)
+
+// WithAbsTol sets the scalar absolute tolerance used in the error norm.
+func WithAbsTol(tol float64) Option {
+	return func(o *Options) { o.AbsTol = tol }
+}
+
+// WithRelTol sets the scalar relative tolerance used in the error norm.
+func WithRelTol(tol float64) Option {
+	return func(o *Options) { o.RelTol = tol }
+}
+
+// WithAbsTolVec sets a per-component absolute tolerance, overriding AbsTol.
+func WithAbsTolVec(tol []float64) Option {
+	return func(o *Options) { o.AbsTolVec = tol }
+}
+
+// WithRelTolVec sets a per-component relative tolerance, overriding RelTol.
+func WithRelTolVec(tol []float64) Option {
+	return func(o *Options) { o.RelTolVec = tol }
+}
+
+// WithInitialStep overrides the automatically estimated initial step size.
+func WithInitialStep(h float64) Option {
+	return func(o *Options) { o.InitialStep = h }
+}
+
+// WithMinStep sets the smallest step size the solver may take before giving
+// up with ErrTooSmallStep.
+func WithMinStep(h float64) Option {
+	return func(o *Options) { o.MinStep = h }
+}
+
+// WithMaxStep caps the step size the controller may grow to.
+func WithMaxStep(h float64) Option {
+	return func(o *Options) { o.MaxStep = h }
+}
+
+// WithMaxSteps caps the number of accepted steps before the solver gives up
+// with ErrTooManySteps.
+func WithMaxSteps(n int) Option {
+	return func(o *Options) { o.MaxSteps = n }
+}
+
+// WithStepObserver registers a callback invoked after every accepted step,
+// letting callers stream results, log progress, or abort the integration by
+// returning a non-nil error.
+func WithStepObserver(f func(x float64, y []float64, h float64) error) Option {
+	return func(o *Options) { o.StepObserver = f }
+}
+
+// WithJacobian sets an analytic Jacobian for implicit solvers such as
+// Radau5, avoiding the finite-difference fallback.
+func WithJacobian(jac func(dfdy [][]float64, x float64, y []float64)) Option {
+	return func(o *Options) { o.Jac = jac }
+}
+
+// WithPIController overrides the (alpha, beta) exponents of the
+// Gustafsson PI step-size controller.
+func WithPIController(alpha, beta float64) Option {
+	return func(o *Options) { o.PIAlpha, o.PIBeta = alpha, beta }
+}
+
+// WithMaxRejections caps the number of consecutive rejected steps before
+// the solver gives up with ErrTooManyRejections.
+func WithMaxRejections(n int) Option {
+	return func(o *Options) { o.MaxRejections = n }
+}
+
+// newOptions applies opts on top of the package defaults.
+func newOptions(opts ...Option) Options {
+	o := Options{
+		AbsTol:   1e-6,
+		RelTol:   1e-3,
+		MaxSteps: 100000,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// tolerance returns the tolerance value derived from o.
+func (o Options) tolerance() tolerance {
+	return tolerance{abs: o.AbsTol, rel: o.RelTol, absVec: o.AbsTolVec, relVec: o.RelTolVec}
+}
+
+// validate checks that any per-component tolerance vectors have the same
+// length as y0, returning a wrapped ErrToleranceVecLength if not.
+func (o Options) validate(n int) error {
+	if o.AbsTolVec != nil && len(o.AbsTolVec) != n {
+		return errors.Wrap(ErrToleranceVecLength, "AbsTolVec")
+	}
+	if o.RelTolVec != nil && len(o.RelTolVec) != n {
+		return errors.Wrap(ErrToleranceVecLength, "RelTolVec")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package diffeq
+
+import "sort"
+
+// denseOutputter is implemented by rungeKutta methods that can interpolate a
+// continuous solution between accepted steps, rather than only reporting
+// the accepted grid points.
+type denseOutputter interface {
+	rungeKutta
+	// interpolate evaluates y(x0+theta*h) into out, given the state y0 and
+	// y1 at the start and end of the step and the stage derivatives k
+	// computed for that step.
+	interpolate(out, y0, y1 []float64, k [][]float64, h, theta float64)
+}
+
+// Solution is a continuous interpolant of an ODE solution, built from the
+// stage derivatives cached at every accepted step. It is valid for x within
+// the xspan the solution was computed over.
+type Solution struct {
+	interp denseOutputter
+
+	xs  []float64   // x at the start of each accepted step.
+	hs  []float64   // step size of each accepted step.
+	y0s [][]float64 // y at the start of each accepted step.
+	y1s [][]float64 // y at the end of each accepted step.
+	ks  [][][]float64
+}
+
+// Evaluate interpolates y(x) into out, which must have the same length as
+// the y0 passed to the solver. x must lie within the solved xspan.
+//
+// Evaluate requires at least one accepted step; it panics if the solver
+// took none, e.g. because xspan[0] >= xspan[1].
+func (s *Solution) Evaluate(x float64, out []float64) {
+	if len(s.xs) < 1 {
+		panic("diffeq: Solution.Evaluate called on a solution with no accepted steps")
+	}
+	i := sort.Search(len(s.xs)-1, func(i int) bool { return s.xs[i+1] >= x })
+	if i > len(s.xs)-1 {
+		i = len(s.xs) - 1
+	}
+
+	theta := (x - s.xs[i]) / s.hs[i]
+	s.interp.interpolate(out, s.y0s[i], s.y1s[i], s.ks[i], s.hs[i], theta)
+}
+
+// appendStep caches the data of one accepted step for later interpolation.
+func (s *Solution) appendStep(x, h float64, y0, y1 []float64, k [][]float64) {
+	s.xs = append(s.xs, x)
+	s.hs = append(s.hs, h)
+	s.y0s = append(s.y0s, append([]float64(nil), y0...))
+	s.y1s = append(s.y1s, append([]float64(nil), y1...))
+
+	kCopy := make([][]float64, len(k))
+	for i, ki := range k {
+		kCopy[i] = append([]float64(nil), ki...)
+	}
+	s.ks = append(s.ks, kCopy)
+}
@@ -0,0 +1,73 @@
+package diffeq
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+var ErrTooManyRejections = errors.Errorf("too many rejected steps")
+
+// stepController implements the Gustafsson PI step-size controller,
+// keeping the previous accepted step's error norm so that
+//
+//	factor = safety * err^(-alpha/(p+1)) * errPrev^(beta/(p+1))
+//
+// which damps the oscillation a pure I-controller (factor depending only on
+// the current error norm) shows on mildly stiff or borderline problems. On
+// a rejected step, the controller falls back to pure I-control and does
+// not update errPrev, since the rejected err is not a reliable predictor.
+type stepController struct {
+	alpha, beta float64
+	safety      float64
+
+	errPrev     float64
+	haveErrPrev bool
+}
+
+// newStepController builds a controller with method-specific (alpha, beta)
+// defaults, overridden by opts if set.
+func newStepController(rk rungeKutta, opts Options) *stepController {
+	alpha, beta := rk.piDefaults()
+	if opts.PIAlpha != 0 {
+		alpha = opts.PIAlpha
+	}
+	if opts.PIBeta != 0 {
+		beta = opts.PIBeta
+	}
+	return &stepController{alpha: alpha, beta: beta, safety: 0.9}
+}
+
+const (
+	stepFactorMax = 10
+	stepFactorMin = 0.2
+)
+
+// accept computes the step-size growth factor for an accepted step with
+// the given error norm (< 1) and order p, and records errPrev for the next
+// call.
+func (c *stepController) accept(errorNorm float64, order int) float64 {
+	var factor float64
+	if errorNorm == 0 {
+		factor = stepFactorMax
+	} else {
+		exponent := c.alpha / (float64(order) + 1)
+		factor = c.safety * math.Pow(errorNorm, -exponent)
+		if c.haveErrPrev {
+			exponentPrev := c.beta / (float64(order) + 1)
+			factor *= math.Pow(c.errPrev, exponentPrev)
+		}
+		factor = min(factor, stepFactorMax)
+	}
+
+	c.errPrev = errorNorm
+	c.haveErrPrev = true
+	return factor
+}
+
+// reject computes the step-size shrink factor for a rejected step (error
+// norm >= 1) using pure I-control; errPrev is left untouched.
+func (c *stepController) reject(errorNorm float64, order int) float64 {
+	exponent := 1 / (float64(order) + 1)
+	return max(c.safety*math.Pow(errorNorm, -exponent), stepFactorMin)
+}